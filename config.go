@@ -0,0 +1,229 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Config holds everything needed to establish a connection to Vertica. It is the
+// programmatic counterpart to a connection string: anything that can't be expressed as a
+// DSN parameter (a pre-built *tls.Config, a custom Dialer, a CredentialProvider) goes
+// here. Use NewConnector(cfg) with sql.OpenDB, or ParseConfig(connString) to obtain one
+// from a DSN and adjust it before connecting.
+type Config struct {
+	// Hosts is the list of host:port pairs to attempt, in order (see LoadBalanceMode).
+	Hosts []string
+
+	// Database is the name of the database to connect to.
+	Database string
+
+	// User is the username to authenticate as.
+	User string
+
+	// Password is the password to authenticate with, used when no CredentialProvider is set.
+	Password string
+
+	// TLSMode selects the built-in TLS behavior: "none" (set by NewConfig and ParseConfig),
+	// "server", "server-strict", "mutual", or the name of a config registered with
+	// RegisterTLSConfig. An empty TLSMode is not treated as "none" and fails to connect as an
+	// unsupported tlsmode. Ignored if TLSConfig is set directly.
+	TLSMode string
+
+	// TLSConfig, if set, is used as-is instead of building one from TLSMode/sslcert/sslkey/
+	// sslrootcert. This is the hook for injecting Vault-issued short-lived certificates or
+	// any other config built outside of a file on disk.
+	TLSConfig *tls.Config
+
+	// Dialer, if set, is used instead of net.Dial to establish the raw TCP connection to
+	// each host. Useful for custom network paths (proxies, service meshes, testing).
+	Dialer func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// TokenSource, if set, is consulted for a bearer token whenever Vertica asks for OAuth
+	// authentication during the handshake, in place of Password. Shaped like
+	// golang.org/x/oauth2.TokenSource so a caller can adapt an existing OIDC client.
+	TokenSource func(ctx context.Context) (string, error)
+
+	// CredentialProvider, if set, is consulted once per Connect(ctx), before the handshake,
+	// for the username and password to authenticate with, in place of User/Password. This
+	// is what makes rotating credentials (e.g. a Vault dynamic database secret) usable with
+	// a long-lived *sql.DB pool: every new connection gets a fresh credential instead of one
+	// embedded in the DSN at startup. See the vertigo/vault sub-package for a Vault-backed
+	// implementation.
+	CredentialProvider CredentialProvider
+
+	// UsePreparedStmts controls whether PrepareContext actually prepares and describes the
+	// statement on the server, or defers that to the first Query/Exec. NewConfig and
+	// ParseConfig set it to true; a Config built as a struct literal starts with false.
+	UsePreparedStmts bool
+
+	// SessionLabel overrides the generated client_label sent during the startup handshake.
+	SessionLabel string
+
+	// ConnectionLoadBalance enables honoring the server's load-balance redirect during the
+	// handshake (load_balance=true or load_balance=random).
+	ConnectionLoadBalance bool
+
+	// LoadBalanceMode additionally selects client-side shuffling of Hosts before the first
+	// connection attempt: "random" shuffles, "" (or "true") leaves Hosts in order and relies
+	// on the server's redirect. Set directly by programmatic callers; populated from the
+	// load_balance DSN parameter by ParseConfig.
+	LoadBalanceMode string
+
+	// Params carries any DSN query parameters not otherwise represented above (sslcert,
+	// sslkey, sslrootcert, oauth_access_token, ...), so that features built on Config don't
+	// require a new struct field for every new DSN parameter.
+	Params url.Values
+}
+
+// CredentialProvider supplies the username and password to authenticate with, consulted
+// once per Connect(ctx) instead of reading a static Config.User/Password. Implement this
+// to support credentials that rotate out from under a long-lived connection pool.
+type CredentialProvider interface {
+	Username(ctx context.Context) (string, error)
+	Password(ctx context.Context) (string, error)
+}
+
+// NewConfig returns a Config with the same defaults ParseConfig applies to an empty DSN.
+func NewConfig() *Config {
+	return &Config{
+		UsePreparedStmts: true,
+		TLSMode:          "none",
+		Params:           url.Values{},
+	}
+}
+
+// ParseConfig parses a vertica:// connection string into a Config. It is the DSN producer
+// of Config; callers that need TLSConfig, Dialer, or other programmatic-only fields should
+// parse the DSN for the rest and then set those fields directly before calling NewConnector.
+func ParseConfig(connString string) (*Config, error) {
+	// url.Parse rejects a multi-host authority whose last entry has no port ("h1:5433,h2"),
+	// so the host list is cut out of the string here and parsed by parseHostList instead.
+	var rawHost string
+	if i := strings.Index(connString, "://"); i >= 0 {
+		rest := connString[i+3:]
+		end := strings.IndexAny(rest, "/?#")
+		if end < 0 {
+			end = len(rest)
+		}
+		at := strings.LastIndex(rest[:end], "@")
+		rawHost = rest[at+1 : end]
+		connString = connString[:i+3] + rest[:at+1] + rest[end:]
+	}
+
+	connURL, err := url.Parse(connString)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := NewConfig()
+
+	if connURL.User != nil {
+		cfg.User = connURL.User.Username()
+		if passwd, isSet := connURL.User.Password(); isSet {
+			cfg.Password = passwd
+		}
+	}
+
+	if len(connURL.Path) > 1 {
+		cfg.Database = connURL.Path[1:]
+	}
+
+	cfg.Hosts = parseHostList(rawHost)
+
+	query := connURL.Query()
+	cfg.Params = query
+
+	if iFlag := query.Get("use_prepared_statements"); iFlag != "" {
+		cfg.UsePreparedStmts = iFlag == "1"
+	}
+
+	cfg.TLSMode = strings.ToLower(query.Get("tlsmode"))
+	if cfg.TLSMode == "" {
+		cfg.TLSMode = "none"
+	}
+
+	switch loadBalance := strings.ToLower(query.Get("load_balance")); loadBalance {
+	case "true", "random":
+		cfg.ConnectionLoadBalance = true
+		cfg.LoadBalanceMode = loadBalance
+	}
+
+	if token := query.Get("oauth_access_token"); token != "" {
+		cfg.TokenSource = staticTokenSource(token)
+	}
+
+	return cfg, nil
+}
+
+// staticTokenSource adapts a fixed bearer token (e.g. the oauth_access_token DSN
+// parameter) to the TokenSource shape expected during the handshake.
+func staticTokenSource(token string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+func (c *Config) validate() error {
+	if len(c.Hosts) == 0 {
+		return fmt.Errorf("connection string must include a host")
+	}
+
+	if c.User == "" && c.CredentialProvider == nil {
+		return fmt.Errorf("connection string must have a non-empty user name")
+	}
+
+	if c.Database == "" {
+		return fmt.Errorf("connection string must include a database name")
+	}
+
+	return nil
+}
+
+// orderedHosts returns cfg.Hosts in the order connection attempts should be made,
+// shuffling when LoadBalanceMode is "random".
+func (c *Config) orderedHosts() []string {
+	hosts := make([]string, len(c.Hosts))
+	copy(hosts, c.Hosts)
+
+	if strings.ToLower(c.LoadBalanceMode) == "random" {
+		shuffleHosts(hosts)
+	}
+
+	return hosts
+}
@@ -0,0 +1,115 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import "testing"
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig("vertica://bob:secret@h1:5433,h2/mydb?load_balance=random&use_prepared_statements=0")
+	if err != nil {
+		t.Fatalf("ParseConfig() returned error: %s", err.Error())
+	}
+
+	if cfg.User != "bob" {
+		t.Errorf("User = %q, want %q", cfg.User, "bob")
+	}
+	if cfg.Password != "secret" {
+		t.Errorf("Password = %q, want %q", cfg.Password, "secret")
+	}
+	if cfg.Database != "mydb" {
+		t.Errorf("Database = %q, want %q", cfg.Database, "mydb")
+	}
+	if want := []string{"h1:5433", "h2:5433"}; !equalStrings(cfg.Hosts, want) {
+		t.Errorf("Hosts = %v, want %v", cfg.Hosts, want)
+	}
+	if !cfg.ConnectionLoadBalance {
+		t.Error("ConnectionLoadBalance = false, want true")
+	}
+	if cfg.LoadBalanceMode != "random" {
+		t.Errorf("LoadBalanceMode = %q, want %q", cfg.LoadBalanceMode, "random")
+	}
+	if cfg.UsePreparedStmts {
+		t.Error("UsePreparedStmts = true, want false")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Hosts = []string{"h1:5433"}
+	cfg.Database = "mydb"
+
+	if err := cfg.validate(); err == nil {
+		t.Fatal("validate() with no User or CredentialProvider should return an error")
+	}
+
+	cfg.User = "bob"
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate() returned unexpected error: %s", err.Error())
+	}
+}
+
+func TestConfigOrderedHosts(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Hosts = []string{"h1:5433", "h2:5433", "h3:5433"}
+
+	hosts := cfg.orderedHosts()
+	if !equalStrings(hosts, cfg.Hosts) {
+		t.Errorf("orderedHosts() = %v, want unshuffled %v", hosts, cfg.Hosts)
+	}
+
+	// A direct, programmatic LoadBalanceMode assignment (no Params set at all) must still
+	// take effect, since Config is built without a DSN in this path.
+	cfg.LoadBalanceMode = "random"
+
+	seenDifferentOrder := false
+	for i := 0; i < 50 && !seenDifferentOrder; i++ {
+		if got := cfg.orderedHosts(); !equalStrings(got, cfg.Hosts) {
+			seenDifferentOrder = true
+		}
+	}
+	if !seenDifferentOrder {
+		t.Error("orderedHosts() with LoadBalanceMode \"random\" never produced a shuffled order")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -41,9 +41,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
-	"net/url"
 	"os"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/vertica/vertica-sql-go/common"
@@ -65,7 +64,9 @@ var (
 // Connection represents a connection to Vertica
 type connection struct {
 	conn             net.Conn
-	connURL          *url.URL
+	config           *Config
+	dial             func(network, addr string) (net.Conn, error)
+	currentHost      string
 	parameters       map[string]string
 	clientPID        int
 	backendPID       uint32
@@ -75,6 +76,12 @@ type connection struct {
 	usePreparedStmts bool
 	sessionID        string
 	serverTZOffset   string
+	user             string
+	password         string
+
+	handlerLock        sync.RWMutex
+	noticeHandler      NoticeHandler
+	paramStatusHandler ParamStatusHandler
 }
 
 // Begin - Begin starts and returns a new transaction. (DEPRECATED)
@@ -132,44 +139,57 @@ func (v *connection) Prepare(query string) (driver.Stmt, error) {
 	return v.PrepareContext(context.Background(), query)
 }
 
-// newConnection constructs a new Vertica Connection object based on the connection string.
-func newConnection(connString string) (*connection, error) {
+// newConnection constructs a new Vertica Connection object from cfg.
+func newConnection(ctx context.Context, cfg *Config) (*connection, error) {
 
-	result := &connection{parameters: make(map[string]string), usePreparedStmts: true}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 
-	var err error
-	result.connURL, err = url.Parse(connString)
+	result := &connection{
+		config:           cfg,
+		parameters:       make(map[string]string),
+		usePreparedStmts: cfg.UsePreparedStmts,
+	}
 
-	if err != nil {
-		return nil, err
+	result.dial = net.Dial
+	if cfg.Dialer != nil {
+		result.dial = func(network, addr string) (net.Conn, error) { return cfg.Dialer(ctx, network, addr) }
 	}
 
-	result.clientPID = os.Getpid()
-	result.sessionID = fmt.Sprintf("%s-%s-%d-%d", driverName, driverVersion, result.clientPID, time.Now().Unix())
+	result.user, result.password = cfg.User, cfg.Password
 
-	// Read the interpolate flag.
-	if iFlag := result.connURL.Query().Get("use_prepared_statements"); iFlag != "" {
-		result.usePreparedStmts = iFlag == "1"
+	var err error
+	if cfg.CredentialProvider != nil {
+		if result.user, err = cfg.CredentialProvider.Username(ctx); err != nil {
+			return nil, fmt.Errorf("credential provider: %s", err.Error())
+		}
+		if result.password, err = cfg.CredentialProvider.Password(ctx); err != nil {
+			return nil, fmt.Errorf("credential provider: %s", err.Error())
+		}
 	}
 
-	sslFlag := strings.ToLower(result.connURL.Query().Get("tlsmode"))
-	if sslFlag == "" {
-		sslFlag = "none"
+	result.clientPID = os.Getpid()
+	result.sessionID = cfg.SessionLabel
+	if result.sessionID == "" {
+		result.sessionID = fmt.Sprintf("%s-%s-%d-%d", driverName, driverVersion, result.clientPID, time.Now().Unix())
 	}
 
-	result.conn, err = net.Dial("tcp", result.connURL.Host)
+	hosts := cfg.orderedHosts()
+
+	result.conn, result.currentHost, err = dialAnyHost(hosts, result.dial)
 
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect to %s (%s)", result.connURL.Host, err.Error())
+		return nil, err
 	}
 
-	if sslFlag != "none" {
-		if err = result.initializeSSL(sslFlag); err != nil {
+	if cfg.TLSConfig != nil || cfg.TLSMode != "none" {
+		if err = result.initializeSSL(cfg.TLSMode); err != nil {
 			return nil, err
 		}
 	}
 
-	if err = result.handshake(); err != nil {
+	if err = result.handshake(ctx); err != nil {
 		return nil, err
 	}
 
@@ -239,35 +259,20 @@ func (v *connection) sendMessage(msg msgs.FrontEndMsg) error {
 
 	if result != nil {
 		connectionLogger.Error("-> FAILED SENDING "+msg.String()+": %v", result.Error())
+		return driver.ErrBadConn
 	}
 
 	return result
 }
 
-func (v *connection) handshake() error {
-
-	if v.connURL.User == nil {
-		return fmt.Errorf("connection string must include a user name")
-	}
-
-	userName := v.connURL.User.Username()
-
-	if len(userName) == 0 {
-		return fmt.Errorf("connection string must have a non-empty user name")
-	}
-
-	if len(v.connURL.Path) <= 1 {
-		return fmt.Errorf("connection string must include a database name")
-	}
-
-	path := v.connURL.Path[1:]
+func (v *connection) handshake(ctx context.Context) error {
 
 	msg := &msgs.FEStartupMsg{
 		ProtocolVersion: protocolVersion,
 		DriverName:      driverName,
 		DriverVersion:   driverVersion,
-		Username:        userName,
-		Database:        path,
+		Username:        v.user,
+		Database:        v.config.Database,
 		SessionID:       v.sessionID,
 		ClientPID:       v.clientPID,
 	}
@@ -291,11 +296,35 @@ func (v *connection) handshake() error {
 			return nil
 		case *msgs.BEParamStatusMsg:
 			v.parameters[msg.ParamName] = msg.ParamValue
+			if msg.ParamName == "TimeZone" {
+				v.serverTZOffset = normalizeTZOffset(msg.ParamValue)
+			}
+			v.fireParamStatus(msg.ParamName, msg.ParamValue)
 		case *msgs.BEKeyDataMsg:
 			v.backendPID = msg.BackendPID
 			v.cancelKey = msg.CancelKey
+		case *msgs.BELoadBalanceMsg:
+			if !v.config.ConnectionLoadBalance {
+				connectionLogger.Warn("ignoring load-balance redirect to %s:%d; ConnectionLoadBalance is not enabled", msg.Host, msg.Port)
+				continue
+			}
+			newConn, err := redirectTo(msg.Host, msg.Port, v.dial)
+			if err != nil {
+				return fmt.Errorf("failed to follow load-balance redirect to %s:%d (%s)", msg.Host, msg.Port, err.Error())
+			}
+			v.conn.Close()
+			v.conn = newConn
+			v.currentHost = net.JoinHostPort(msg.Host, fmt.Sprintf("%d", msg.Port))
+
+			if v.config.TLSConfig != nil || v.config.TLSMode != "none" {
+				if err := v.initializeSSL(v.config.TLSMode); err != nil {
+					return fmt.Errorf("failed to establish TLS on redirected node %s (%s)", v.currentHost, err.Error())
+				}
+			}
+
+			return v.handshake(ctx)
 		default:
-			_, err = v.defaultMessageHandler(msg)
+			_, err = v.defaultMessageHandler(ctx, msg)
 			if err != nil {
 				return err
 			}
@@ -337,7 +366,31 @@ func (v *connection) initializeSession() error {
 	return nil
 }
 
-func (v *connection) defaultMessageHandler(bMsg msgs.BackEndMsg) (bool, error) {
+// normalizeTZOffset converts a TimeZone ParameterStatus value, which the server may send
+// as a zone name (e.g. "America/New_York") rather than a numeric offset, into the ±HH
+// shape initializeSession derives from now()::timestamptz, or ±HH:MM for a zone whose
+// offset is not a whole number of hours. A value that can't be resolved to a location is
+// returned unchanged.
+func normalizeTZOffset(value string) string {
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		connectionLogger.Warn("couldn't resolve TimeZone value %q to a location; using it as serverTZOffset as-is", value)
+		return value
+	}
+
+	now := time.Now().In(loc)
+
+	// Most zones sit on a whole-hour offset, matching the "-07" shape initializeSession
+	// derives; a half/quarter-hour zone (e.g. Asia/Kolkata, UTC+5:30) needs the minutes too,
+	// since truncating them would silently produce a wrong, not just differently-shaped, offset.
+	if _, offset := now.Zone(); offset%3600 != 0 {
+		return now.Format("-07:00")
+	}
+
+	return now.Format("-07")
+}
+
+func (v *connection) defaultMessageHandler(ctx context.Context, bMsg msgs.BackEndMsg) (bool, error) {
 
 	handled := true
 
@@ -354,12 +407,20 @@ func (v *connection) defaultMessageHandler(bMsg msgs.BackEndMsg) (bool, error) {
 			err = v.authSendMD5Password(msg.ExtraAuthData)
 		case common.AuthenticationSHA512Password:
 			err = v.authSendSHA512Password(msg.ExtraAuthData)
+		case common.AuthenticationOAuth:
+			err = v.authSendOAuthToken(ctx)
 		default:
 			handled = false
 			err = fmt.Errorf("unsupported authentication scheme: %d", msg.Response)
 		}
 	case *msgs.BENoticeMsg:
-		break
+		v.fireNotice(Notice{Severity: msg.Severity, Message: msg.Message})
+	case *msgs.BEParamStatusMsg:
+		v.parameters[msg.ParamName] = msg.ParamValue
+		if msg.ParamName == "TimeZone" {
+			v.serverTZOffset = normalizeTZOffset(msg.ParamValue)
+		}
+		v.fireParamStatus(msg.ParamName, msg.ParamValue)
 	default:
 		handled = false
 		err = fmt.Errorf("unhandled message: %v", msg)
@@ -375,7 +436,10 @@ func (v *connection) readAll(buf []byte) error {
 		bytesRead, err := v.conn.Read(buf[readIndex:])
 
 		if err != nil {
-			return err
+			// The transport is dead; tell database/sql to discard this connection and
+			// dial a fresh one (which will fail over to another host) instead of retrying
+			// reads on a socket that will never return data again.
+			return driver.ErrBadConn
 		}
 
 		readIndex += bytesRead
@@ -405,46 +469,60 @@ func (v *connection) initializeSSL(sslFlag string) error {
 		return fmt.Errorf("SSL/TLS probe gave unknown response: %c", buf[0])
 	}
 
+	if v.config.TLSConfig != nil {
+		connectionLogger.Info("enabling SSL/TLS with caller-supplied tls.Config")
+		v.conn = tls.Client(v.conn, v.config.TLSConfig)
+		return nil
+	}
+
 	switch sslFlag {
 	case "server":
 		connectionLogger.Info("enabling SSL/TLS server mode")
 		v.conn = tls.Client(v.conn, &tls.Config{InsecureSkipVerify: true})
 	case "server-strict":
 		connectionLogger.Info("enabling SSL/TLS server strict mode")
-		v.conn = tls.Client(v.conn, &tls.Config{ServerName: v.connURL.Hostname()})
+		v.conn = tls.Client(v.conn, &tls.Config{ServerName: v.hostname()})
+	case "mutual":
+		connectionLogger.Info("enabling SSL/TLS mutual (client certificate) mode")
+		tlsConfig, err := v.buildMutualTLSConfig()
+		if err != nil {
+			connectionLogger.Error(err.Error())
+			return err
+		}
+		v.conn = tls.Client(v.conn, tlsConfig)
 	default:
-		err := fmt.Errorf("unsupported tlsmode flag: %s - should be 'server', 'server-strict' or 'none'", sslFlag)
+		if tlsConfig, ok := getRegisteredTLSConfig(sslFlag); ok {
+			connectionLogger.Info("enabling SSL/TLS with registered config '%s'", sslFlag)
+			v.conn = tls.Client(v.conn, tlsConfig)
+			break
+		}
+
+		err := fmt.Errorf("unsupported tlsmode flag: %s - should be 'server', 'server-strict', 'mutual', 'none', or a name registered with RegisterTLSConfig", sslFlag)
 		connectionLogger.Error(err.Error())
 		return err
 	}
-	// 	case "mutual":
-	// 		err = fmt.Errorf("mutual ssl mode not currently supported")
-	// 	default:
-	// 		err = fmt.Errorf("unsupported ssl value in connect string: %s", sslFlag)
 
 	return nil
 }
 
-func (v *connection) authSendPlainTextPassword() error {
-	passwd, isSet := v.connURL.User.Password()
-
-	if !isSet {
-		passwd = ""
+// hostname returns the bare host (no port) of the node we're currently connected to, for
+// use as the TLS ServerName.
+func (v *connection) hostname() string {
+	host, _, err := net.SplitHostPort(v.currentHost)
+	if err != nil {
+		return v.currentHost
 	}
+	return host
+}
 
-	msg := &msgs.FEPasswordMsg{PasswordData: passwd}
+func (v *connection) authSendPlainTextPassword() error {
+	msg := &msgs.FEPasswordMsg{PasswordData: v.password}
 
 	return v.sendMessage(msg)
 }
 
 func (v *connection) authSendMD5Password(extraAuthData []byte) error {
-	passwd, isSet := v.connURL.User.Password()
-
-	if !isSet {
-		passwd = ""
-	}
-
-	hash1 := fmt.Sprintf("%x", md5.Sum([]byte(passwd+v.connURL.User.Username())))
+	hash1 := fmt.Sprintf("%x", md5.Sum([]byte(v.password+v.user)))
 	hash2 := fmt.Sprintf("md5%x", md5.Sum(append([]byte(hash1), extraAuthData[0:4]...)))
 
 	msg := &msgs.FEPasswordMsg{PasswordData: hash2}
@@ -452,14 +530,21 @@ func (v *connection) authSendMD5Password(extraAuthData []byte) error {
 	return v.sendMessage(msg)
 }
 
-func (v *connection) authSendSHA512Password(extraAuthData []byte) error {
-	passwd, isSet := v.connURL.User.Password()
+func (v *connection) authSendOAuthToken(ctx context.Context) error {
+	if v.config.TokenSource == nil {
+		return fmt.Errorf("server requested OAuth authentication but no TokenSource or oauth_access_token was configured")
+	}
 
-	if !isSet {
-		passwd = ""
+	token, err := v.config.TokenSource(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to obtain OAuth access token: %s", err.Error())
 	}
 
-	hash1 := fmt.Sprintf("%x", sha512.Sum512(append([]byte(passwd), extraAuthData[8:]...)))
+	return v.sendMessage(&msgs.FEPasswordMsg{PasswordData: token})
+}
+
+func (v *connection) authSendSHA512Password(extraAuthData []byte) error {
+	hash1 := fmt.Sprintf("%x", sha512.Sum512(append([]byte(v.password), extraAuthData[8:]...)))
 	hash2 := fmt.Sprintf("sha512%x", sha512.Sum512(append([]byte(hash1), extraAuthData[0:4]...)))
 
 	msg := &msgs.FEPasswordMsg{PasswordData: hash2}
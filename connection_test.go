@@ -0,0 +1,69 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeTZOffset(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata not available in this environment: %s", err.Error())
+	}
+	want := time.Now().In(loc).Format("-07")
+
+	if got := normalizeTZOffset("America/New_York"); got != want {
+		t.Errorf("normalizeTZOffset(%q) = %q, want %q", "America/New_York", got, want)
+	}
+}
+
+func TestNormalizeTZOffsetHalfHour(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("tzdata not available in this environment: %s", err.Error())
+	}
+	want := time.Now().In(loc).Format("-07:00")
+
+	if got := normalizeTZOffset("Asia/Kolkata"); got != want {
+		t.Errorf("normalizeTZOffset(%q) = %q, want %q", "Asia/Kolkata", got, want)
+	}
+}
+
+func TestNormalizeTZOffsetUnresolvable(t *testing.T) {
+	const value = "not-a-real-zone"
+	if got := normalizeTZOffset(value); got != value {
+		t.Errorf("normalizeTZOffset(%q) = %q, want input unchanged: %q", value, got, value)
+	}
+}
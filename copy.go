@@ -0,0 +1,229 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vertica/vertica-sql-go/msgs"
+)
+
+// copyEnclosure quotes each field written by WriteRow, per Vertica's COPY ... ENCLOSED BY
+// syntax, so that delimiter and newline characters embedded in a value are treated as
+// literal data instead of corrupting the row/column layout of the load.
+const copyEnclosure = `"`
+
+// CopyOptions configures a COPY FROM STDIN load started with (*connection).CopyIn.
+type CopyOptions struct {
+	// Delimiter separates fields encoded by CopyStream.WriteRow. Defaults to ','.
+	Delimiter string
+	// RejectedDataTable, if set, captures rows Vertica rejects in the named table instead
+	// of aborting the whole load.
+	RejectedDataTable string
+}
+
+// CopyStream streams row data to the server for an in-progress COPY ... FROM STDIN.
+type CopyStream interface {
+	// Write sends raw, already-delimited bytes as a CopyData frame.
+	Write(data []byte) (int, error)
+	// WriteRow encodes values with the stream's delimiter and sends them as a CopyData frame.
+	WriteRow(values []driver.Value) error
+	// Close sends CopyDone and waits for the server to report the load complete.
+	Close() error
+}
+
+type copyStream struct {
+	ctx       context.Context
+	conn      *connection
+	delimiter string
+	closed    bool
+}
+
+// Copier exposes CopyIn, reached through the database/sql escape hatch since connection is
+// unexported:
+//
+//	err := conn.Raw(func(driverConn interface{}) error {
+//	    stream, err := driverConn.(vertigo.Copier).CopyIn(ctx, "t1", nil, vertigo.CopyOptions{})
+//	    ...
+//	})
+type Copier interface {
+	// CopyIn begins a Vertica COPY ... FROM STDIN bulk load into table (all columns, or
+	// only the named columns when len(columns) > 0) and returns a CopyStream to write rows
+	// to. This is the bulk-load counterpart to prepared-statement inserts and is far faster
+	// for loading large numbers of rows.
+	CopyIn(ctx context.Context, table string, columns []string, opts CopyOptions) (CopyStream, error)
+}
+
+var _ Copier = (*connection)(nil)
+
+// CopyIn implements Copier.
+func (v *connection) CopyIn(ctx context.Context, table string, columns []string, opts CopyOptions) (CopyStream, error) {
+	delimiter := opts.Delimiter
+	if delimiter == "" {
+		delimiter = ","
+	}
+
+	if err := v.sendMessage(&msgs.FEQueryMsg{Query: buildCopyInQuery(table, columns, delimiter, opts.RejectedDataTable)}); err != nil {
+		return nil, err
+	}
+
+	for {
+		bMsg, err := v.recvMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		switch msg := bMsg.(type) {
+		case *msgs.BECopyInResponseMsg:
+			return &copyStream{ctx: ctx, conn: v, delimiter: delimiter}, nil
+		case *msgs.BEErrorMsg:
+			return nil, msg.ToErrorType()
+		default:
+			if _, err := v.defaultMessageHandler(ctx, bMsg); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func buildCopyInQuery(table string, columns []string, delimiter, rejectedDataTable string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "COPY %s", table)
+
+	if len(columns) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(columns, ", "))
+	}
+
+	fmt.Fprintf(&b, " FROM STDIN DELIMITER '%s' ENCLOSED BY '%s'", delimiter, copyEnclosure)
+
+	if rejectedDataTable != "" {
+		fmt.Fprintf(&b, " REJECTED DATA AS TABLE %s", rejectedDataTable)
+	}
+
+	return b.String()
+}
+
+func (c *copyStream) Write(data []byte) (int, error) {
+	if c.closed {
+		return 0, fmt.Errorf("copy stream is closed")
+	}
+
+	if err := c.conn.sendMessage(&msgs.FECopyDataMsg{Data: data}); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+func (c *copyStream) WriteRow(values []driver.Value) error {
+	var row bytes.Buffer
+
+	for i, value := range values {
+		if i > 0 {
+			row.WriteString(c.delimiter)
+		}
+		if value == nil {
+			continue
+		}
+		row.WriteString(copyEnclosure)
+		row.WriteString(encodeCopyValue(value))
+		row.WriteString(copyEnclosure)
+	}
+
+	row.WriteByte('\n')
+
+	_, err := c.Write(row.Bytes())
+	return err
+}
+
+// encodeCopyValue renders value as a COPY literal matching what Vertica expects for that
+// Go type, then backslash-escapes any embedded backslash or enclosure character, per
+// Vertica's default ESCAPE AS '\' convention, so it survives inside the ENCLOSED BY
+// quoting WriteRow wraps every field in.
+func encodeCopyValue(value driver.Value) string {
+	// The hex literal's leading backslash is required COPY syntax, not data that could
+	// itself contain a backslash or enclosure character, so it bypasses escaping below.
+	if b, ok := value.([]byte); ok {
+		return "\\x" + hex.EncodeToString(b)
+	}
+
+	var s string
+	switch v := value.(type) {
+	case time.Time:
+		s = v.Format("2006-01-02 15:04:05.999999-07:00")
+	default:
+		s = fmt.Sprintf("%v", v)
+	}
+
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, copyEnclosure, `\`+copyEnclosure)
+}
+
+func (c *copyStream) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	if err := c.conn.sendMessage(&msgs.FECopyDoneMsg{}); err != nil {
+		return err
+	}
+
+	for {
+		bMsg, err := c.conn.recvMessage()
+		if err != nil {
+			return err
+		}
+
+		switch msg := bMsg.(type) {
+		case *msgs.BECommandCompleteMsg:
+			return nil
+		case *msgs.BEErrorMsg:
+			return msg.ToErrorType()
+		case *msgs.BEReadyForQueryMsg:
+			c.conn.transactionState = msg.TransactionState
+			return nil
+		default:
+			if _, err := c.conn.defaultMessageHandler(c.ctx, bMsg); err != nil {
+				return err
+			}
+		}
+	}
+}
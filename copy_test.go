@@ -0,0 +1,166 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeCopyConn is a minimal net.Conn that serves a fixed read payload and captures
+// everything written to it, so CopyIn/WriteRow can be driven without a real server.
+type fakeCopyConn struct {
+	net.Conn
+	readBuf  *bytes.Buffer
+	writeBuf bytes.Buffer
+}
+
+func (f *fakeCopyConn) Read(p []byte) (int, error)  { return f.readBuf.Read(p) }
+func (f *fakeCopyConn) Write(p []byte) (int, error) { return f.writeBuf.Write(p) }
+func (f *fakeCopyConn) Close() error                { return nil }
+
+// copyInResponseFrame builds the wire bytes for a BECopyInResponseMsg with no columns,
+// matching the framing (*connection).recvMessage expects: a 1-byte tag, a 4-byte
+// big-endian length covering itself plus the payload, then the payload.
+func copyInResponseFrame() []byte {
+	payload := []byte{0, 0, 0} // OverallFormat=0, NumColumns=0
+	frame := make([]byte, 0, 5+len(payload))
+	frame = append(frame, 'G')
+	lengthBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBytes, uint32(len(payload)+4))
+	frame = append(frame, lengthBytes...)
+	frame = append(frame, payload...)
+	return frame
+}
+
+func TestConnectionCopyInAndWriteRow(t *testing.T) {
+	fc := &fakeCopyConn{readBuf: bytes.NewBuffer(copyInResponseFrame())}
+	v := &connection{conn: fc, parameters: map[string]string{}}
+
+	stream, err := v.CopyIn(context.Background(), "t1", nil, CopyOptions{})
+	if err != nil {
+		t.Fatalf("CopyIn() returned error: %s", err.Error())
+	}
+
+	fc.writeBuf.Reset() // drop the captured COPY query frame; only WriteRow's framing matters below
+
+	if err := stream.WriteRow([]driver.Value{"a,b", nil, 42}); err != nil {
+		t.Fatalf("WriteRow() returned error: %s", err.Error())
+	}
+
+	wantRow := []byte(`"a,b",,"42"` + "\n")
+	wantFrame := append([]byte{'d'}, make([]byte, 4)...)
+	binary.BigEndian.PutUint32(wantFrame[1:5], uint32(len(wantRow)+4))
+	wantFrame = append(wantFrame, wantRow...)
+
+	if !bytes.Equal(fc.writeBuf.Bytes(), wantFrame) {
+		t.Errorf("WriteRow() wrote %q, want %q", fc.writeBuf.Bytes(), wantFrame)
+	}
+}
+
+func TestCopyStreamWriteAfterClose(t *testing.T) {
+	cs := &copyStream{conn: &connection{conn: &fakeCopyConn{readBuf: &bytes.Buffer{}}}, delimiter: ",", closed: true}
+
+	if _, err := cs.Write([]byte("x")); err == nil {
+		t.Error("Write() after Close() should return an error")
+	}
+}
+
+func TestBuildCopyInQuery(t *testing.T) {
+	tests := []struct {
+		name              string
+		table             string
+		columns           []string
+		delimiter         string
+		rejectedDataTable string
+		want              string
+	}{
+		{
+			name:      "all columns",
+			table:     "t1",
+			delimiter: ",",
+			want:      `COPY t1 FROM STDIN DELIMITER ',' ENCLOSED BY '"'`,
+		},
+		{
+			name:      "named columns",
+			table:     "t1",
+			columns:   []string{"a", "b"},
+			delimiter: ",",
+			want:      `COPY t1 (a, b) FROM STDIN DELIMITER ',' ENCLOSED BY '"'`,
+		},
+		{
+			name:              "with rejected data table",
+			table:             "t1",
+			delimiter:         "|",
+			rejectedDataTable: "t1_rejects",
+			want:              `COPY t1 FROM STDIN DELIMITER '|' ENCLOSED BY '"' REJECTED DATA AS TABLE t1_rejects`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildCopyInQuery(tt.table, tt.columns, tt.delimiter, tt.rejectedDataTable)
+			if got != tt.want {
+				t.Errorf("buildCopyInQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeCopyValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "string with embedded quote", value: `say "hi"`, want: `say \"hi\"`},
+		{name: "string with embedded delimiter", value: "a,b", want: "a,b"},
+		{name: "string with embedded backslash", value: `C:\new\data`, want: `C:\\new\\data`},
+		{name: "bytes", value: []byte{0xde, 0xad, 0xbe, 0xef}, want: `\xdeadbeef`},
+		{name: "time", value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), want: "2024-01-02 03:04:05+00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := encodeCopyValue(tt.value)
+			if got != tt.want {
+				t.Errorf("encodeCopyValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
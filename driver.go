@@ -33,6 +33,7 @@ package vertigo
 // THE SOFTWARE.
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
@@ -55,14 +56,34 @@ var driverLogger = logger.New("driver")
 
 // Open takes a connection string in this format:
 // user:pass@host:port/database
+// From interface: sql.driver.Driver
 func (d *Driver) Open(connString string) (driver.Conn, error) {
-	conn, err := newConnection(connString)
+	connector, err := d.OpenConnector(connString)
+	if err != nil {
+		driverLogger.Error(fmt.Sprint(err))
+		return nil, err
+	}
+
+	conn, err := connector.Connect(context.Background())
 	if err != nil {
 		driverLogger.Error(fmt.Sprint(err))
 	}
 	return conn, err
 }
 
+// OpenConnector parses connString into a Config and returns a driver.Connector bound to
+// it. Callers that need programmatic-only Config fields (TLSConfig, Dialer, a
+// CredentialProvider) should call ParseConfig and NewConnector directly instead.
+// From interface: sql.driver.DriverContext
+func (d *Driver) OpenConnector(connString string) (driver.Connector, error) {
+	cfg, err := ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnector(cfg), nil
+}
+
 // Register ourselves with the sql package.
 func init() {
 	logger.SetLogLevel(logger.WARN)
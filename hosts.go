@@ -0,0 +1,93 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+)
+
+const defaultPort = "5433"
+
+// parseHostList splits the comma-separated host[:port] authority of a connection URL
+// (e.g. "h1:5433,h2:5433,h3:5433") into individual host:port pairs, filling in
+// defaultPort for any entry that omits it.
+func parseHostList(rawHost string) []string {
+	parts := strings.Split(rawHost, ",")
+	hosts := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(part); err != nil {
+			part = net.JoinHostPort(part, defaultPort)
+		}
+		hosts = append(hosts, part)
+	}
+
+	return hosts
+}
+
+// shuffleHosts randomizes host order in place for load_balance=random.
+func shuffleHosts(hosts []string) {
+	rand.Shuffle(len(hosts), func(i, j int) { hosts[i], hosts[j] = hosts[j], hosts[i] })
+}
+
+// dialAnyHost attempts each host in turn, using dial to establish each candidate
+// connection, and returns the first successful one.
+func dialAnyHost(hosts []string, dial func(network, addr string) (net.Conn, error)) (net.Conn, string, error) {
+	var lastErr error
+
+	for _, host := range hosts {
+		conn, err := dial("tcp", host)
+		if err == nil {
+			return conn, host, nil
+		}
+		connectionLogger.Warn("failed to connect to %s (%s); trying next host", host, err.Error())
+		lastErr = err
+	}
+
+	return nil, "", fmt.Errorf("cannot connect to any of %v (%s)", hosts, lastErr.Error())
+}
+
+// redirectTo dials the node the server suggested in a BELoadBalanceMsg received during
+// the startup handshake, using dial to establish the raw connection.
+func redirectTo(host string, port uint32, dial func(network, addr string) (net.Conn, error)) (net.Conn, error) {
+	addr := net.JoinHostPort(host, fmt.Sprintf("%d", port))
+	connectionLogger.Info("redirecting to node %s per server load-balance response", addr)
+	return dial("tcp", addr)
+}
@@ -0,0 +1,67 @@
+package msgs
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// BECopyInResponseMsg is sent by the server after a COPY ... FROM STDIN query to signal
+// that it is ready to receive CopyData frames from the client.
+type BECopyInResponseMsg struct {
+	OverallFormat byte
+	ColumnFormats []uint16
+}
+
+func parseBECopyInResponseMsg(data []byte) (BackEndMsg, error) {
+	if len(data) < 3 {
+		return nil, fmt.Errorf("malformed CopyInResponse message")
+	}
+
+	res := &BECopyInResponseMsg{OverallFormat: data[0]}
+
+	numCols := int(binary.BigEndian.Uint16(data[1:3]))
+	offset := 3
+
+	for i := 0; i < numCols && offset+2 <= len(data); i++ {
+		res.ColumnFormats = append(res.ColumnFormats, binary.BigEndian.Uint16(data[offset:offset+2]))
+		offset += 2
+	}
+
+	return res, nil
+}
+
+func (m *BECopyInResponseMsg) String() string {
+	return fmt.Sprintf("CopyInResponse: OverallFormat=%d, NumColumns=%d", m.OverallFormat, len(m.ColumnFormats))
+}
@@ -0,0 +1,85 @@
+package msgs
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestCreateBackEndMsgLoadBalance(t *testing.T) {
+	data := append([]byte("node02\x00"), 0, 0, 0x15, 0x41)
+
+	bMsg, err := CreateBackEndMsg(tagLoadBalance, data)
+	if err != nil {
+		t.Fatalf("CreateBackEndMsg() returned error: %s", err.Error())
+	}
+
+	msg, ok := bMsg.(*BELoadBalanceMsg)
+	if !ok {
+		t.Fatalf("CreateBackEndMsg() returned %T, want *BELoadBalanceMsg", bMsg)
+	}
+
+	if msg.Host != "node02" {
+		t.Errorf("Host = %q, want %q", msg.Host, "node02")
+	}
+
+	wantPort := binary.BigEndian.Uint32(data[len("node02")+1:])
+	if msg.Port != wantPort {
+		t.Errorf("Port = %d, want %d", msg.Port, wantPort)
+	}
+}
+
+func TestCreateBackEndMsgLoadBalanceTruncated(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "no null terminator", data: []byte("node02")},
+		{name: "port truncated", data: append([]byte("node02\x00"), 0, 0, 0x15)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateBackEndMsg(tagLoadBalance, tt.data); err == nil {
+				t.Fatal("CreateBackEndMsg() with a truncated load balance message should return an error, not panic")
+			}
+		})
+	}
+}
+
+func TestCreateBackEndMsgUnrecognized(t *testing.T) {
+	if _, err := CreateBackEndMsg('?', nil); err == nil {
+		t.Fatal("CreateBackEndMsg() with an unrecognized tag should return an error")
+	}
+}
@@ -0,0 +1,79 @@
+package msgs
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import "fmt"
+
+// FECopyDataMsg carries a chunk of row data for an in-progress COPY ... FROM STDIN.
+type FECopyDataMsg struct {
+	Data []byte
+}
+
+// Flatten docs
+func (m *FECopyDataMsg) Flatten() ([]byte, byte) {
+	return m.Data, 'd'
+}
+
+func (m *FECopyDataMsg) String() string {
+	return fmt.Sprintf("CopyData: %d bytes", len(m.Data))
+}
+
+// FECopyDoneMsg tells the server that the client has sent all CopyData frames for the
+// current COPY ... FROM STDIN.
+type FECopyDoneMsg struct{}
+
+// Flatten docs
+func (m *FECopyDoneMsg) Flatten() ([]byte, byte) {
+	return []byte{}, 'c'
+}
+
+func (m *FECopyDoneMsg) String() string {
+	return "CopyDone"
+}
+
+// FECopyFailMsg aborts an in-progress COPY ... FROM STDIN with the given reason.
+type FECopyFailMsg struct {
+	ErrorMessage string
+}
+
+// Flatten docs
+func (m *FECopyFailMsg) Flatten() ([]byte, byte) {
+	buf := newMsgBuffer()
+	buf.appendBytes([]byte(m.ErrorMessage))
+	buf.appendBytes([]byte{0})
+	return buf.bytes(), 'f'
+}
+
+func (m *FECopyFailMsg) String() string {
+	return fmt.Sprintf("CopyFail: '%s'", m.ErrorMessage)
+}
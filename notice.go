@@ -0,0 +1,101 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Notice is a server notice (e.g. a ROLLBACK or ANALYZE advisory) delivered outside the
+// normal result-set flow.
+type Notice struct {
+	Severity string
+	Message  string
+}
+
+// NoticeHandler is called for each Notice the server sends during the life of a connection.
+type NoticeHandler func(Notice)
+
+// ParamStatusHandler is called whenever the server reports a new or changed session
+// parameter (server_version, client_encoding, TimeZone after a SET, ...).
+type ParamStatusHandler func(name, value string)
+
+// RawConn exposes vertigo-specific functionality that doesn't fit database/sql/driver's
+// interfaces, reached through the database/sql escape hatch:
+//
+//	err := conn.Raw(func(driverConn interface{}) error {
+//	    driverConn.(vertigo.RawConn).RegisterNoticeHandler(func(n vertigo.Notice) {
+//	        log.Printf("[%s] %s", n.Severity, n.Message)
+//	    })
+//	    return nil
+//	})
+type RawConn interface {
+	// RegisterNoticeHandler sets the callback invoked for server notices. Pass nil to stop
+	// receiving them.
+	RegisterNoticeHandler(NoticeHandler)
+	// RegisterParamStatusHandler sets the callback invoked when a session parameter
+	// changes. Pass nil to stop receiving them.
+	RegisterParamStatusHandler(ParamStatusHandler)
+}
+
+var _ RawConn = (*connection)(nil)
+
+// RegisterNoticeHandler implements RawConn.
+func (v *connection) RegisterNoticeHandler(handler NoticeHandler) {
+	v.handlerLock.Lock()
+	defer v.handlerLock.Unlock()
+	v.noticeHandler = handler
+}
+
+// RegisterParamStatusHandler implements RawConn.
+func (v *connection) RegisterParamStatusHandler(handler ParamStatusHandler) {
+	v.handlerLock.Lock()
+	defer v.handlerLock.Unlock()
+	v.paramStatusHandler = handler
+}
+
+func (v *connection) fireNotice(n Notice) {
+	v.handlerLock.RLock()
+	handler := v.noticeHandler
+	v.handlerLock.RUnlock()
+
+	if handler != nil {
+		handler(n)
+	}
+}
+
+func (v *connection) fireParamStatus(name, value string) {
+	v.handlerLock.RLock()
+	handler := v.paramStatusHandler
+	v.handlerLock.RUnlock()
+
+	if handler != nil {
+		handler(name, value)
+	}
+}
@@ -0,0 +1,119 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+var (
+	tlsConfigLock sync.Mutex
+	tlsConfigs    = make(map[string]*tls.Config)
+)
+
+// RegisterTLSConfig registers a *tls.Config under a name that can then be referenced
+// from a connection string as tlsmode=<name>. This is the escape hatch for callers who
+// need to build the config programmatically (e.g. a Vault-issued short-lived client
+// certificate) instead of pointing at files on disk via sslcert/sslkey/sslrootcert.
+func RegisterTLSConfig(name string, config *tls.Config) error {
+	switch name {
+	case "", "none", "server", "server-strict", "mutual":
+		return fmt.Errorf("tlsmode name '%s' is reserved", name)
+	}
+
+	tlsConfigLock.Lock()
+	defer tlsConfigLock.Unlock()
+
+	tlsConfigs[name] = config
+	return nil
+}
+
+// DeregisterTLSConfig removes a config registered with RegisterTLSConfig.
+func DeregisterTLSConfig(name string) {
+	tlsConfigLock.Lock()
+	defer tlsConfigLock.Unlock()
+
+	delete(tlsConfigs, name)
+}
+
+func getRegisteredTLSConfig(name string) (*tls.Config, bool) {
+	tlsConfigLock.Lock()
+	defer tlsConfigLock.Unlock()
+
+	cfg, ok := tlsConfigs[name]
+	return cfg, ok
+}
+
+// buildMutualTLSConfig loads the client certificate/key pair and CA bundle named by the
+// sslcert, sslkey and sslrootcert connection string parameters for tlsmode=mutual.
+func (v *connection) buildMutualTLSConfig() (*tls.Config, error) {
+	params := v.config.Params
+
+	certFile := params.Get("sslcert")
+	keyFile := params.Get("sslkey")
+	rootCertFile := params.Get("sslrootcert")
+
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("tlsmode=mutual requires sslcert and sslkey to be set")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate/key pair: %s", err.Error())
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ServerName:   v.hostname(),
+	}
+
+	if rootCertFile != "" {
+		rootCertBytes, err := ioutil.ReadFile(rootCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sslrootcert: %s", err.Error())
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(rootCertBytes) {
+			return nil, fmt.Errorf("failed to parse any certificates from sslrootcert: %s", rootCertFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,75 @@
+package vertigo
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestRegisterTLSConfigReservedNames(t *testing.T) {
+	for _, name := range []string{"", "none", "server", "server-strict", "mutual"} {
+		if err := RegisterTLSConfig(name, &tls.Config{}); err == nil {
+			t.Errorf("RegisterTLSConfig(%q, ...) should have been rejected as reserved", name)
+		}
+	}
+}
+
+func TestRegisterTLSConfigRoundTrip(t *testing.T) {
+	defer DeregisterTLSConfig("custom")
+
+	want := &tls.Config{ServerName: "example.com"}
+	if err := RegisterTLSConfig("custom", want); err != nil {
+		t.Fatalf("RegisterTLSConfig() returned error: %s", err.Error())
+	}
+
+	got, ok := getRegisteredTLSConfig("custom")
+	if !ok {
+		t.Fatal("getRegisteredTLSConfig() did not find the registered config")
+	}
+	if got != want {
+		t.Error("getRegisteredTLSConfig() returned a different *tls.Config than was registered")
+	}
+
+	DeregisterTLSConfig("custom")
+
+	if _, ok := getRegisteredTLSConfig("custom"); ok {
+		t.Error("getRegisteredTLSConfig() found a config after DeregisterTLSConfig")
+	}
+}
+
+func TestGetRegisteredTLSConfigUnknown(t *testing.T) {
+	if _, ok := getRegisteredTLSConfig("does-not-exist"); ok {
+		t.Error("getRegisteredTLSConfig() should return ok=false for an unregistered name")
+	}
+}
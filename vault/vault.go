@@ -0,0 +1,136 @@
+package vault
+
+// Copyright (c) 2019 Micro Focus or one of its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package vault implements vertigo.CredentialProvider backed by a HashiCorp Vault
+// database secrets engine, for Vertica deployments that issue short-lived, per-client
+// credentials instead of a single static password.
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+
+	"github.com/vertica/vertica-sql-go/logger"
+)
+
+var vaultLogger = logger.New("vault")
+
+// defaultRenewBefore is how far ahead of lease expiry a cached credential is considered
+// stale and re-read, so a connection being opened never races the lease actually expiring.
+const defaultRenewBefore = 30 * time.Second
+
+// CredentialProvider reads a Vertica database secret from Vault at Path, caching it for
+// the lease duration and re-reading shortly before it would expire. It satisfies
+// vertigo.CredentialProvider, so it can be set directly as Config.CredentialProvider.
+type CredentialProvider struct {
+	client *vaultapi.Client
+	path   string
+
+	renewBefore time.Duration
+
+	mu        sync.Mutex
+	username  string
+	password  string
+	expiresAt time.Time
+}
+
+// NewCredentialProvider returns a CredentialProvider that reads database credentials from
+// path (e.g. "database/creds/vertica-readonly") using client.
+func NewCredentialProvider(client *vaultapi.Client, path string) *CredentialProvider {
+	return &CredentialProvider{
+		client:      client,
+		path:        path,
+		renewBefore: defaultRenewBefore,
+	}
+}
+
+// Username returns the currently cached Vault-issued username, reading the secret path
+// again first if the cached one is missing or close to expiring. The existing lease is
+// not renewed; a new secret is read in its place.
+func (p *CredentialProvider) Username(ctx context.Context) (string, error) {
+	if err := p.ensureFresh(ctx); err != nil {
+		return "", err
+	}
+	return p.username, nil
+}
+
+// Password returns the currently cached Vault-issued password, reading the secret path
+// again first if the cached one is missing or close to expiring. The existing lease is
+// not renewed; a new secret is read in its place.
+func (p *CredentialProvider) Password(ctx context.Context) (string, error) {
+	if err := p.ensureFresh(ctx); err != nil {
+		return "", err
+	}
+	return p.password, nil
+}
+
+func (p *CredentialProvider) ensureFresh(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Now().Before(p.expiresAt) {
+		return nil
+	}
+
+	secret, err := p.client.Logical().ReadWithContext(ctx, p.path)
+	if err != nil {
+		return fmt.Errorf("vault: failed to read %s: %s", p.path, err.Error())
+	}
+
+	if secret == nil || secret.Data == nil {
+		return fmt.Errorf("vault: no secret data at %s", p.path)
+	}
+
+	username, _ := secret.Data["username"].(string)
+	password, _ := secret.Data["password"].(string)
+
+	if username == "" || password == "" {
+		return fmt.Errorf("vault: secret at %s is missing username/password", p.path)
+	}
+
+	leaseDuration := time.Duration(secret.LeaseDuration) * time.Second
+	if leaseDuration <= p.renewBefore {
+		leaseDuration = p.renewBefore + time.Second
+	}
+
+	p.username = username
+	p.password = password
+	p.expiresAt = time.Now().Add(leaseDuration - p.renewBefore)
+
+	vaultLogger.Info("refreshed Vertica credentials from %s; next refresh in %s", p.path, leaseDuration-p.renewBefore)
+
+	return nil
+}